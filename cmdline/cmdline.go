@@ -11,6 +11,10 @@ type CmdLine struct {
 	Raw   string
 	AsMap map[string]string
 	Err   error
+
+	// values holds every occurrence of each flag, in order; AsMap only
+	// ever keeps the last one. See Values.
+	values map[string][]string
 }
 
 // NewCmdLine returns a populated CmdLine struct
@@ -29,7 +33,7 @@ func parse(cmdlineReader io.Reader) *CmdLine {
 	line.Err = err
 	// This works because string(nil) is ""
 	line.Raw = strings.TrimRight(string(raw), "\n")
-	line.AsMap = parseToMap(line.Raw)
+	line.AsMap, line.values = parseToMap(line.Raw)
 	return line
 }
 
@@ -76,17 +80,27 @@ func doParse(input string, handler func(flag, key, canonicalKey, value, trimmedV
 	}
 }
 
-// parseToMap turns a space-separated kernel commandline into a map
-func parseToMap(input string) map[string]string {
+// parseToMap turns a space-separated kernel commandline into a map of the
+// last value seen for each flag, and a map of every value seen for each
+// flag, in order.
+func parseToMap(input string) (map[string]string, map[string][]string) {
 	flagMap := make(map[string]string)
+	values := make(map[string][]string)
 	doParse(input, func(flag, key, canonicalKey, value, trimmedValue string) {
 		// We store the value twice, once with dash, once with underscores
 		// Just in case people check with the wrong method
 		flagMap[canonicalKey] = trimmedValue
 		flagMap[key] = trimmedValue
+		values[canonicalKey] = append(values[canonicalKey], trimmedValue)
+		// Only append under key too if it differs from canonicalKey,
+		// otherwise every no-dash flag (the common case) gets recorded
+		// twice per occurrence.
+		if key != canonicalKey {
+			values[key] = append(values[key], trimmedValue)
+		}
 	})
 
-	return flagMap
+	return flagMap, values
 }
 
 // ContainsFlag verifies that the kernel cmdline has a flag set
@@ -102,6 +116,15 @@ func (c *CmdLine) Flag(flag string) (string, bool) {
 	return value, present
 }
 
+// Values returns every occurrence of flag, in order, for flags that may
+// be repeated on the command line (e.g. console=tty0 console=ttyS0,115200).
+// Flag only ever returns the last occurrence; Values returns them all,
+// and nil if the flag was never set.
+func (c *CmdLine) Values(flag string) []string {
+	canonicalFlag := strings.Replace(flag, "-", "_", -1)
+	return c.values[canonicalFlag]
+}
+
 func (c *CmdLine) AsBool(flag string) bool {
 	canonicalFlag := strings.Replace(flag, "-", "_", -1)
 	value, present := c.AsMap[canonicalFlag]
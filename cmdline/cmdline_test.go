@@ -53,6 +53,99 @@ func TestCmdline(t *testing.T) {
 	}
 }
 
+func TestValues(t *testing.T) {
+	exampleCmdLine := `console=tty0 console=ttyS0,115200 timeout=10s`
+	c := FromString(exampleCmdLine)
+
+	got := c.Values("console")
+	want := []string{"tty0", "ttyS0,115200"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("Values(\"console\") = %v, want %v", got, want)
+	}
+
+	if got := c.Values("nope"); got != nil {
+		t.Errorf("Values(\"nope\") = %v, want nil", got)
+	}
+}
+
+func TestTypedAccessors(t *testing.T) {
+	exampleCmdLine := `test2-flag=8 crashkernel=128M ` +
+		`console=ttyS0,115200 timeout=10s ratio=0.5 pid=4294967296`
+	c := FromString(exampleCmdLine)
+
+	if i, ok := c.AsInt("test2-flag"); !ok || i != 8 {
+		t.Errorf("AsInt(\"test2-flag\") = %v, %v; want 8, true", i, ok)
+	}
+	if _, ok := c.AsInt("missing"); ok {
+		t.Error("AsInt(\"missing\") reported present")
+	}
+
+	if u, ok := c.AsUint64("pid"); !ok || u != 4294967296 {
+		t.Errorf("AsUint64(\"pid\") = %v, %v; want 4294967296, true", u, ok)
+	}
+
+	if f, ok := c.AsFloat("ratio"); !ok || f != 0.5 {
+		t.Errorf("AsFloat(\"ratio\") = %v, %v; want 0.5, true", f, ok)
+	}
+
+	if d, ok := c.AsDuration("timeout"); !ok || d.String() != "10s" {
+		t.Errorf("AsDuration(\"timeout\") = %v, %v; want 10s, true", d, ok)
+	}
+
+	if b, ok := c.AsBytes("crashkernel"); !ok || b != 128*1024*1024 {
+		t.Errorf("AsBytes(\"crashkernel\") = %v, %v; want %d, true", b, ok, 128*1024*1024)
+	}
+
+	want := []string{"ttyS0", "115200"}
+	got := c.AsList("console", ",")
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("AsList(\"console\", \",\") = %v, want %v", got, want)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	exampleCmdLine := `boot_image=/vmlinuz-4.11.2 ro test-flag test2-flag=8 ` +
+		`uroot.initflags="systemd test-flag=3 runlevel=2" ` +
+		`console=tty0 console=ttyS0,115200 fsck.repair=yes`
+	c := FromString(exampleCmdLine)
+
+	type initFlags struct {
+		Systemd  bool `cmdline:"systemd,bool"`
+		Runlevel int  `cmdline:"runlevel"`
+	}
+	type config struct {
+		BootImage  string    `cmdline:"boot_image"`
+		Test2Flag  int       `cmdline:"test2-flag"`
+		FsckRepair bool      `cmdline:"fsck.repair,bool"`
+		Console    []string  `cmdline:"console,repeat"`
+		InitFlags  initFlags `cmdline:"uroot.initflags"`
+	}
+
+	var cfg config
+	if err := Unmarshal(c, &cfg); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+
+	if cfg.BootImage != "/vmlinuz-4.11.2" {
+		t.Errorf("BootImage = %q, want /vmlinuz-4.11.2", cfg.BootImage)
+	}
+	if cfg.Test2Flag != 8 {
+		t.Errorf("Test2Flag = %d, want 8", cfg.Test2Flag)
+	}
+	if !cfg.FsckRepair {
+		t.Error("FsckRepair = false, want true")
+	}
+	if want := []string{"tty0", "ttyS0,115200"}; len(cfg.Console) != 2 || cfg.Console[0] != want[0] || cfg.Console[1] != want[1] {
+		t.Errorf("Console = %v, want %v", cfg.Console, want)
+	}
+	if !cfg.InitFlags.Systemd {
+		t.Error("InitFlags.Systemd = false, want true")
+	}
+	if cfg.InitFlags.Runlevel != 2 {
+		t.Errorf("InitFlags.Runlevel = %d, want 2", cfg.InitFlags.Runlevel)
+	}
+}
+
 type badreader struct{}
 
 // Read implements io.Reader, always returning io.ErrClosedPipe
@@ -0,0 +1,109 @@
+package cmdline
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AsInt returns the value of flag parsed as a base-10 integer, and
+// whether the flag was both set and a valid integer.
+func (c *CmdLine) AsInt(flag string) (int, bool) {
+	value, present := c.Flag(flag)
+	if !present {
+		return 0, false
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+	return i, true
+}
+
+// AsUint64 returns the value of flag parsed as a base-10 unsigned
+// integer, and whether the flag was both set and valid.
+func (c *CmdLine) AsUint64(flag string) (uint64, bool) {
+	value, present := c.Flag(flag)
+	if !present {
+		return 0, false
+	}
+	u, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return u, true
+}
+
+// AsFloat returns the value of flag parsed as a float, and whether the
+// flag was both set and valid.
+func (c *CmdLine) AsFloat(flag string) (float64, bool) {
+	value, present := c.Flag(flag)
+	if !present {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, false
+	}
+	return f, true
+}
+
+// AsDuration returns the value of flag parsed with time.ParseDuration
+// (e.g. "10s", "5m"), and whether the flag was both set and valid.
+func (c *CmdLine) AsDuration(flag string) (time.Duration, bool) {
+	value, present := c.Flag(flag)
+	if !present {
+		return 0, false
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, false
+	}
+	return d, true
+}
+
+// AsBytes returns the value of flag parsed as a byte size, and whether
+// the flag was both set and valid. It accepts a bare integer (bytes) or
+// one suffixed with K, M, or G for binary (1024-based) multiples, as
+// used by kernel flags like crashkernel=128M.
+func (c *CmdLine) AsBytes(flag string) (uint64, bool) {
+	value, present := c.Flag(flag)
+	if !present {
+		return 0, false
+	}
+	return parseBytes(value)
+}
+
+func parseBytes(value string) (uint64, bool) {
+	if value == "" {
+		return 0, false
+	}
+	mult := uint64(1)
+	digits := value
+	switch value[len(value)-1] {
+	case 'k', 'K':
+		mult = 1 << 10
+		digits = value[:len(value)-1]
+	case 'm', 'M':
+		mult = 1 << 20
+		digits = value[:len(value)-1]
+	case 'g', 'G':
+		mult = 1 << 30
+		digits = value[:len(value)-1]
+	}
+	n, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n * mult, true
+}
+
+// AsList splits the value of flag on sep, e.g. a comma-separated list
+// like console=ttyS0,115200. It returns nil if the flag isn't set.
+func (c *CmdLine) AsList(flag, sep string) []string {
+	value, present := c.Flag(flag)
+	if !present {
+		return nil
+	}
+	return strings.Split(value, sep)
+}
@@ -0,0 +1,139 @@
+package cmdline
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Unmarshal fills the struct pointed to by v from c, one field at a time.
+// Each exported field is bound by a `cmdline:"name[,opt]"` tag, where
+// name is the cmdline flag to read; a field with no tag is bound to its
+// own name, lower-cased. opt may be:
+//
+//   - bool:   the field must be a bool; it is set via AsBool.
+//   - repeat: the field must be a []string; it is set to every occurrence
+//     of the flag, via Values.
+//
+// A struct field is treated as a nested cmdline: the named flag's value
+// (e.g. uroot.initflags="systemd test-flag=3") is parsed as its own
+// CmdLine via FromString and Unmarshal is applied to it recursively.
+//
+// Fields for flags that were not set are left unchanged.
+func Unmarshal(c *CmdLine, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cmdline: Unmarshal: v must be a non-nil pointer to a struct, got %T", v)
+	}
+	return unmarshalStruct(c, rv.Elem())
+}
+
+func unmarshalStruct(c *CmdLine, rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts := parseTag(field)
+		fv := rv.Field(i)
+
+		if fv.Kind() == reflect.Struct {
+			value, present := c.Flag(name)
+			if !present {
+				continue
+			}
+			sub := FromString(value)
+			if sub.Err != nil {
+				return fmt.Errorf("cmdline: Unmarshal: field %s: parsing nested %q: %w", field.Name, name, sub.Err)
+			}
+			if err := unmarshalStruct(sub, fv); err != nil {
+				return fmt.Errorf("cmdline: Unmarshal: field %s: %w", field.Name, err)
+			}
+			continue
+		}
+
+		switch {
+		case hasOpt(opts, "repeat"):
+			if fv.Kind() != reflect.Slice || fv.Type().Elem().Kind() != reflect.String {
+				return fmt.Errorf("cmdline: Unmarshal: field %s is tagged repeat but is not []string", field.Name)
+			}
+			if values := c.Values(name); values != nil {
+				fv.Set(reflect.ValueOf(values))
+			}
+		case hasOpt(opts, "bool"):
+			if fv.Kind() != reflect.Bool {
+				return fmt.Errorf("cmdline: Unmarshal: field %s is tagged bool but is not bool", field.Name)
+			}
+			if c.ContainsFlag(name) {
+				fv.SetBool(c.AsBool(name))
+			}
+		default:
+			value, present := c.Flag(name)
+			if !present {
+				continue
+			}
+			if err := setScalar(fv, value); err != nil {
+				return fmt.Errorf("cmdline: Unmarshal: field %s: %w", field.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// parseTag splits a field's `cmdline:"name,opt1,opt2"` tag into the flag
+// name to read and its options, defaulting name to the field's own name,
+// lower-cased, when there is no tag or no name in it.
+func parseTag(field reflect.StructField) (name string, opts []string) {
+	tag := field.Tag.Get("cmdline")
+	if tag == "" {
+		return strings.ToLower(field.Name), nil
+	}
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name, parts[1:]
+}
+
+func hasOpt(opts []string, want string) bool {
+	for _, opt := range opts {
+		if opt == want {
+			return true
+		}
+	}
+	return false
+}
+
+func setScalar(fv reflect.Value, value string) error {
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+	case reflect.Bool:
+		fv.SetBool(value == "1" || strings.EqualFold(value, "true") || strings.EqualFold(value, "yes"))
+	default:
+		return fmt.Errorf("unsupported field kind %s", fv.Kind())
+	}
+	return nil
+}
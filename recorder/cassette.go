@@ -0,0 +1,267 @@
+package recorder
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// cassetteVersion is written to every saved Cassette so future format
+// changes can be detected on load.
+const cassetteVersion = 1
+
+// Request is the structured, diff-friendly form of an http.Request
+// stored in a Cassette interaction.
+type Request struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+	// BodyEncoding is "base64" when Body holds a base64-encoded body
+	// because the raw bytes weren't valid UTF-8 (e.g. a binary upload),
+	// and empty when Body is the body text itself. See encodeBody.
+	BodyEncoding string     `json:"body_encoding,omitempty"`
+	Form         url.Values `json:"form,omitempty"`
+}
+
+// Response is the structured form of an http.Response stored in a
+// Cassette interaction.
+type Response struct {
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+	// BodyEncoding is "base64" when Body holds a base64-encoded body
+	// because the raw bytes weren't valid UTF-8 (e.g. a binary download),
+	// and empty when Body is the body text itself. See encodeBody.
+	BodyEncoding string        `json:"body_encoding,omitempty"`
+	Duration     time.Duration `json:"duration"`
+}
+
+// Interaction pairs a recorded Request with the Response it produced.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Cassette is a single file holding an ordered list of interactions, in
+// the style of the go-vcr/Ruby VCR ecosystem. Unlike the hash-keyed
+// Record/Replay pair, interactions are matched in recorded order, so the
+// same request made twice with two different responses round-trips
+// correctly, and the file stays human-editable and diff-friendly.
+type Cassette struct {
+	Version      int           `json:"version"`
+	RecordedAt   time.Time     `json:"recorded_at"`
+	Interactions []Interaction `json:"interactions"`
+
+	path string
+	mu   sync.Mutex
+	// played tracks which interactions Replay has already consumed, kept
+	// in lockstep with Interactions.
+	played []bool
+}
+
+// NewCassette returns a Cassette backed by path. If path already exists
+// it is loaded immediately, so Replay has interactions to match against;
+// a missing file is not an error, since Record will create it on first
+// use.
+func NewCassette(path string) *Cassette {
+	c := &Cassette{path: path, Version: cassetteVersion}
+	if b, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(b, c)
+	}
+	c.played = make([]bool, len(c.Interactions))
+	return c
+}
+
+// MatchFunc reports whether recorded is the interaction that should
+// answer req. The default, used unless overridden with WithMatcher, is
+// MatchMethodURL.
+type MatchFunc func(req *http.Request, recorded Request) bool
+
+// MatchMethodURL matches a request to a recorded interaction by method
+// and URL only.
+func MatchMethodURL(req *http.Request, recorded Request) bool {
+	return req.Method == recorded.Method && req.URL.String() == recorded.URL
+}
+
+// Option configures Cassette.Replay. Cassette.Record accepts options too,
+// but WithMatcher, the only Option, has no effect on recording.
+type Option func(*cassetteOptions)
+
+type cassetteOptions struct {
+	match MatchFunc
+}
+
+// WithMatcher overrides the MatchFunc used to pair incoming requests with
+// recorded interactions.
+func WithMatcher(m MatchFunc) Option {
+	return func(o *cassetteOptions) { o.match = m }
+}
+
+func newCassetteOptions(opts []Option) *cassetteOptions {
+	o := &cassetteOptions{match: MatchMethodURL}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Record returns an http.RoundTripper that executes requests through rt
+// (http.DefaultTransport if nil), appending each request/response pair to
+// the cassette as a new Interaction and saving it to disk after every
+// call.
+func (c *Cassette) Record(rt http.RoundTripper, opts ...Option) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		recordedReq, err := toRequest(req)
+		if err != nil {
+			return nil, fmt.Errorf("cassette: recording request: %w", err)
+		}
+
+		start := time.Now()
+		res, err := rt.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		duration := time.Since(start)
+
+		recordedRes, body, err := toResponse(res, duration)
+		if err != nil {
+			return nil, fmt.Errorf("cassette: recording response: %w", err)
+		}
+		res.Body = io.NopCloser(bytes.NewReader(body))
+
+		c.mu.Lock()
+		c.Interactions = append(c.Interactions, Interaction{Request: recordedReq, Response: recordedRes})
+		c.played = append(c.played, false)
+		c.RecordedAt = time.Now()
+		saveErr := c.save()
+		c.mu.Unlock()
+		if saveErr != nil {
+			return nil, fmt.Errorf("cassette: saving %s: %w", c.path, saveErr)
+		}
+		return res, nil
+	})
+}
+
+// Replay returns an http.RoundTripper that answers requests from the
+// cassette's interactions instead of making them over the network.
+// Interactions are tried in recorded order, skipping ones a previous call
+// already consumed, so a request repeated with a different recorded
+// response each time replays in the order it was recorded.
+func (c *Cassette) Replay(opts ...Option) http.RoundTripper {
+	o := newCassetteOptions(opts)
+	return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		for i, interaction := range c.Interactions {
+			if c.played[i] || !o.match(req, interaction.Request) {
+				continue
+			}
+			c.played[i] = true
+			return fromResponse(interaction.Response, req)
+		}
+		return nil, fmt.Errorf("cassette: no unplayed interaction matches %s %s", req.Method, req.URL)
+	})
+}
+
+func (c *Cassette) save() error {
+	c.Version = cassetteVersion
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, b, 0o644)
+}
+
+func toRequest(req *http.Request) (Request, error) {
+	var body, encoding string
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			return Request{}, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(b))
+		body, encoding = encodeBody(b)
+	}
+	return Request{
+		Method:       req.Method,
+		URL:          req.URL.String(),
+		Headers:      req.Header.Clone(),
+		Body:         body,
+		BodyEncoding: encoding,
+		Form:         req.Form,
+	}, nil
+}
+
+func toResponse(res *http.Response, duration time.Duration) (Response, []byte, error) {
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return Response{}, nil, err
+	}
+	res.Body.Close()
+	text, encoding := encodeBody(body)
+	return Response{
+		Status:       res.StatusCode,
+		Headers:      res.Header.Clone(),
+		Body:         text,
+		BodyEncoding: encoding,
+		Duration:     duration,
+	}, body, nil
+}
+
+func fromResponse(r Response, req *http.Request) (*http.Response, error) {
+	header := r.Headers.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	body, err := decodeBody(r.Body, r.BodyEncoding)
+	if err != nil {
+		return nil, fmt.Errorf("cassette: decoding response body: %w", err)
+	}
+	return &http.Response{
+		Status:     http.StatusText(r.Status),
+		StatusCode: r.Status,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     header,
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+// encodeBody returns b as-is when it's valid UTF-8, so the common
+// text/JSON case stays human-readable and diff-friendly in the saved
+// cassette file, and base64-encodes it otherwise (e.g. a binary body),
+// since encoding/json would otherwise silently replace invalid bytes
+// with U+FFFD on marshal and corrupt it.
+func encodeBody(b []byte) (body, encoding string) {
+	if utf8.Valid(b) {
+		return string(b), ""
+	}
+	return base64.StdEncoding.EncodeToString(b), "base64"
+}
+
+// decodeBody reverses encodeBody.
+func decodeBody(body, encoding string) ([]byte, error) {
+	switch encoding {
+	case "":
+		return []byte(body), nil
+	case "base64":
+		return base64.StdEncoding.DecodeString(body)
+	default:
+		return nil, fmt.Errorf("unknown body encoding %q", encoding)
+	}
+}
@@ -6,13 +6,16 @@ import (
 	"context"
 	"crypto/md5"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"net/http"
 	"net/http/httputil"
 	"os"
 	"path/filepath"
+	"regexp"
 )
 
 // RoundTripFunc is an adaptor to use a function as an http.RoundTripper.
@@ -31,6 +34,127 @@ type (
 type Filters struct {
 	RequestFilter
 	ResponseFilter
+	// ScrubBody is run on the raw dumped bytes of both the request and
+	// the response, after RequestFilter/ResponseFilter, and before they
+	// are written to disk. Use it (e.g. via ScrubPattern) to redact
+	// secrets such as API keys or rotating auth tokens so the recorded
+	// fixture can be safely committed.
+	ScrubBody func(b []byte) []byte
+}
+
+func (f *Filters) scrub(b []byte) []byte {
+	if f == nil || f.ScrubBody == nil {
+		return b
+	}
+	return f.ScrubBody(b)
+}
+
+// ScrubPattern returns a Filters.ScrubBody function that replaces every
+// match of pattern with replacement.
+func ScrubPattern(pattern *regexp.Regexp, replacement string) func([]byte) []byte {
+	return func(b []byte) []byte {
+		return pattern.ReplaceAll(b, []byte(replacement))
+	}
+}
+
+// Matcher computes a stable lookup key for req. RecordWith/ReplayWith
+// hash that key to name the request/response files an interaction is
+// stored under, so any two requests that produce the same key read and
+// write the same fixture.
+type Matcher func(req *http.Request) (key string, err error)
+
+// defaultMatcher reproduces the original Record/Replay behavior: the key
+// is the full dumped request, headers included, so any header a client
+// library adds (User-Agent, Authorization, Date, ...) changes which
+// fixture is read or written. Record and Replay use it; prefer one of
+// the Match* matchers below, or RecordWith/ReplayWith with a custom one,
+// for anything less brittle.
+func defaultMatcher(req *http.Request) (string, error) {
+	b, err := httputil.DumpRequest(req, true)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// MatchMethodURLOnly keys solely on request method and URL, ignoring
+// headers and body entirely.
+func MatchMethodURLOnly(req *http.Request) (string, error) {
+	return req.Method + " " + req.URL.String(), nil
+}
+
+// MatchMethodURLBody keys on method, URL, and the raw request body.
+func MatchMethodURLBody(req *http.Request) (string, error) {
+	body, err := readAndRestoreBody(req)
+	if err != nil {
+		return "", err
+	}
+	return req.Method + " " + req.URL.String() + "\n" + string(body), nil
+}
+
+// MatchIgnoringHeaders returns a Matcher that keys on the full dumped
+// request with the named headers (case-insensitive) removed first. Use
+// it to stop a client library's own added headers from busting the
+// match, without giving up header matching entirely.
+func MatchIgnoringHeaders(headers ...string) Matcher {
+	return func(req *http.Request) (string, error) {
+		clone := req.Clone(req.Context())
+		clone.Body = req.Body
+		for _, h := range headers {
+			clone.Header.Del(h)
+		}
+		b, err := httputil.DumpRequest(clone, true)
+		req.Body = clone.Body
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+}
+
+// MatchJSONBody keys like MatchMethodURLBody, but canonicalizes a JSON
+// request body first (by unmarshalling and re-marshalling it), so bodies
+// that are semantically equal but differ in key order or whitespace
+// still match. A non-JSON or empty body is treated as MatchMethodURLBody
+// would treat it.
+func MatchJSONBody(req *http.Request) (string, error) {
+	body, err := readAndRestoreBody(req)
+	if err != nil {
+		return "", err
+	}
+	canon, err := canonicalJSON(body)
+	if err != nil {
+		// Not JSON: key on the raw body, as MatchMethodURLBody does.
+		canon = string(body)
+	}
+	return req.Method + " " + req.URL.String() + "\n" + canon, nil
+}
+
+func readAndRestoreBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func canonicalJSON(body []byte) (string, error) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return string(body), nil
+	}
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
 }
 
 // Record returns an http.RoundTripper that writes out its
@@ -38,9 +162,18 @@ type Filters struct {
 // Requests are named according to a hash of their contents.
 // Responses are named according to the request that made them.
 func Record(rt http.RoundTripper, basepath string, f *Filters) http.RoundTripper {
+	return RecordWith(rt, basepath, defaultMatcher, f)
+}
+
+// RecordWith is Record with a custom Matcher controlling how a request
+// is keyed, instead of hashing the full request dump (headers included).
+func RecordWith(rt http.RoundTripper, basepath string, m Matcher, f *Filters) http.RoundTripper {
 	if rt == nil {
 		rt = http.DefaultTransport
 	}
+	if m == nil {
+		m = defaultMatcher
+	}
 	return RoundTripFunc(func(req *http.Request) (res *http.Response, err error) {
 		defer func() {
 			if err != nil {
@@ -57,15 +190,21 @@ func Record(rt http.RoundTripper, basepath string, f *Filters) http.RoundTripper
 			f.RequestFilter(dumpReq)
 		}
 
+		key, err := m(dumpReq)
+		if err != nil {
+			return nil, err
+		}
+
 		b, err := httputil.DumpRequest(dumpReq, true)
 		if err != nil {
 			return nil, err
 		}
+		b = f.scrub(b)
 
 		// restore body
 		req.Body = dumpReq.Body
 
-		reqname, resname := buildName(b)
+		reqname, resname := buildName(key)
 		name := filepath.Join(basepath, reqname)
 		if err := os.WriteFile(name, b, 0o644); err != nil {
 			return nil, err
@@ -80,6 +219,7 @@ func Record(rt http.RoundTripper, basepath string, f *Filters) http.RoundTripper
 		if err != nil {
 			return nil, err
 		}
+		b = f.scrub(b)
 		name = filepath.Join(basepath, resname)
 		if err := os.WriteFile(name, b, 0o644); err != nil {
 			return nil, err
@@ -102,17 +242,27 @@ var errNotFound = errors.New("response not found")
 // Responses are looked up according to a hash of the request.
 // Response file names may optionally be prefixed with comments for better human organization.
 func ReplayFS(fsys fs.FS) http.RoundTripper {
+	return ReplayWith(fsys, defaultMatcher)
+}
+
+// ReplayWith is ReplayFS with a custom Matcher controlling how a request
+// is keyed to look up its recorded response, instead of hashing the full
+// request dump (headers included).
+func ReplayWith(fsys fs.FS, m Matcher) http.RoundTripper {
+	if m == nil {
+		m = defaultMatcher
+	}
 	return RoundTripFunc(func(req *http.Request) (res *http.Response, err error) {
 		defer func() {
 			if err != nil {
 				err = fmt.Errorf("problem while replaying transport: %w", err)
 			}
 		}()
-		b, err := httputil.DumpRequest(req, true)
+		key, err := m(req)
 		if err != nil {
 			return nil, err
 		}
-		_, name := buildName(b)
+		_, name := buildName(key)
 		glob := "*" + name
 		matches, err := fs.Glob(fsys, glob)
 		if err != nil {
@@ -124,7 +274,7 @@ func ReplayFS(fsys fs.FS) http.RoundTripper {
 		if len(matches) > 1 {
 			return nil, fmt.Errorf("ambiguous response: multiple replay files match %q", glob)
 		}
-		b, err = fs.ReadFile(fsys, matches[0])
+		b, err := fs.ReadFile(fsys, matches[0])
 		if err != nil {
 			return nil, err
 		}
@@ -133,9 +283,9 @@ func ReplayFS(fsys fs.FS) http.RoundTripper {
 	})
 }
 
-func buildName(b []byte) (reqname, resname string) {
+func buildName(key string) (reqname, resname string) {
 	h := md5.New()
-	h.Write(b)
+	h.Write([]byte(key))
 	s := base64.URLEncoding.EncodeToString(h.Sum(nil))
 	return s[:8] + ".req.txt", s[:8] + ".res.txt"
 }
@@ -0,0 +1,192 @@
+package recorder
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestRecordReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "hello from "+r.URL.Path)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	client := &http.Client{Transport: Record(nil, dir, nil)}
+
+	res, err := client.Get(srv.URL + "/greet")
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatalf("ReadAll() = %v", err)
+	}
+	if want := "hello from /greet"; string(body) != want {
+		t.Fatalf("recorded body = %q, want %q", body, want)
+	}
+
+	replayClient := &http.Client{Transport: Replay(dir)}
+	res, err = replayClient.Get(srv.URL + "/greet")
+	if err != nil {
+		t.Fatalf("replayed Get() = %v", err)
+	}
+	body, err = io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatalf("ReadAll() = %v", err)
+	}
+	if want := "hello from /greet"; string(body) != want {
+		t.Errorf("replayed body = %q, want %q", body, want)
+	}
+}
+
+func TestRecordWithReplayWithCustomMatcher(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "should-be-ignored-by-matcher")
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	client := &http.Client{Transport: RecordWith(nil, dir, MatchMethodURLOnly, nil)}
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/thing", nil)
+	if err != nil {
+		t.Fatalf("NewRequest() = %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer recording-token")
+	if _, err := client.Do(req); err != nil {
+		t.Fatalf("Do() = %v", err)
+	}
+
+	replayClient := &http.Client{Transport: ReplayWith(os.DirFS(dir), MatchMethodURLOnly)}
+	req, err = http.NewRequest(http.MethodGet, srv.URL+"/thing", nil)
+	if err != nil {
+		t.Fatalf("NewRequest() = %v", err)
+	}
+	// A different Authorization header must still match, since
+	// MatchMethodURLOnly ignores headers entirely.
+	req.Header.Set("Authorization", "Bearer replay-token")
+	res, err := replayClient.Do(req)
+	if err != nil {
+		t.Fatalf("replayed Do() = %v", err)
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatalf("ReadAll() = %v", err)
+	}
+	if string(body) != "ok" {
+		t.Errorf("replayed body = %q, want %q", body, "ok")
+	}
+}
+
+func TestCassetteRecordReplay(t *testing.T) {
+	count := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		if count == 1 {
+			io.WriteString(w, "first")
+		} else {
+			io.WriteString(w, "second")
+		}
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	cassette := NewCassette(path)
+	client := &http.Client{Transport: cassette.Record(nil)}
+
+	for _, want := range []string{"first", "second"} {
+		res, err := client.Get(srv.URL + "/thing")
+		if err != nil {
+			t.Fatalf("Get() = %v", err)
+		}
+		body, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			t.Fatalf("ReadAll() = %v", err)
+		}
+		if string(body) != want {
+			t.Fatalf("recorded body = %q, want %q", body, want)
+		}
+	}
+
+	replay := NewCassette(path)
+	replayClient := &http.Client{Transport: replay.Replay()}
+	for _, want := range []string{"first", "second"} {
+		res, err := replayClient.Get(srv.URL + "/thing")
+		if err != nil {
+			t.Fatalf("replayed Get() = %v", err)
+		}
+		body, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			t.Fatalf("ReadAll() = %v", err)
+		}
+		if string(body) != want {
+			t.Errorf("replayed body = %q, want %q", body, want)
+		}
+	}
+
+	if _, err := replayClient.Get(srv.URL + "/thing"); err == nil {
+		t.Error("replaying a third time should fail: no unplayed interaction left")
+	}
+}
+
+func TestCassetteRecordReplayBinaryBody(t *testing.T) {
+	want := []byte{0xff, 0xfe, 0x00, 0x01, 0x80, 0x81, 0xc0, 0xc1}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(want)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	cassette := NewCassette(path)
+	client := &http.Client{Transport: cassette.Record(nil)}
+
+	res, err := client.Get(srv.URL + "/thing")
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatalf("ReadAll() = %v", err)
+	}
+	if !bytes.Equal(body, want) {
+		t.Fatalf("recorded body = %x, want %x", body, want)
+	}
+
+	replay := NewCassette(path)
+	replayClient := &http.Client{Transport: replay.Replay()}
+	res, err = replayClient.Get(srv.URL + "/thing")
+	if err != nil {
+		t.Fatalf("replayed Get() = %v", err)
+	}
+	body, err = io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatalf("ReadAll() = %v", err)
+	}
+	if !bytes.Equal(body, want) {
+		t.Errorf("replayed body = %x, want %x", body, want)
+	}
+}
+
+func TestScrubPattern(t *testing.T) {
+	scrub := ScrubPattern(regexp.MustCompile(`sk-[a-zA-Z0-9]+`), "REDACTED")
+	in := []byte(`{"api_key":"sk-abc123"}`)
+	out := scrub(in)
+	if string(out) != `{"api_key":"REDACTED"}` {
+		t.Errorf("scrub() = %q, want %q", out, `{"api_key":"REDACTED"}`)
+	}
+}
@@ -0,0 +1,104 @@
+package template
+
+import (
+	"fmt"
+	"html/template"
+	"regexp"
+)
+
+// extendsPattern matches a leading {{ extends "name" }} directive, the
+// marker for template inheritance. It must be the first thing in the
+// file (leading whitespace aside); everything after it is expected to be
+// a series of {{ define "block" }}...{{ end }} blocks.
+var extendsPattern = regexp.MustCompile(`^\s*\{\{-?\s*extends\s+"([^"]+)"\s*-?\}\}\s*\n?`)
+
+// parseExtends reports whether src opens with an extends directive, and
+// if so returns the parent template name along with the remainder of src
+// with that directive stripped.
+func parseExtends(src string) (parent string, rest string, ok bool) {
+	loc := extendsPattern.FindStringSubmatchIndex(src)
+	if loc == nil {
+		return "", src, false
+	}
+	return src[loc[2]:loc[3]], src[loc[1]:], true
+}
+
+// resolveExtends compiles the template named name, which extends
+// e.extendsOf[name], into a renderable *template.Template and memoizes
+// it in e.extended. Parent chains are resolved recursively, so a child
+// of a child of a base layout works the same as a single level of
+// extends; resolving is used to detect and report cycles. bases
+// memoizes isolatedBase lookups for the duration of one resolution pass.
+func (e *Engine) resolveExtends(name string, bases map[string]*template.Template, resolving map[string]bool) (*template.Template, error) {
+	if tmpl, ok := e.extended[name]; ok {
+		return tmpl, nil
+	}
+	if resolving[name] {
+		return nil, fmt.Errorf("template: %s: extends cycle detected", name)
+	}
+	resolving[name] = true
+	defer delete(resolving, name)
+
+	parentName := e.extendsOf[name]
+	var parent *template.Template
+	var err error
+	if _, parentExtends := e.extendsOf[parentName]; parentExtends {
+		parent, err = e.resolveExtends(parentName, bases, resolving)
+	} else {
+		parent, err = e.isolatedBase(parentName, bases)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if parent == nil {
+		return nil, fmt.Errorf("template: %s: extends %q: template not found", name, parentName)
+	}
+
+	clone, err := parent.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("template: %s: cloning %q: %w", name, parentName, err)
+	}
+	// Parse the child's {{ define }} blocks under a throwaway name so we
+	// only register/override the blocks themselves, without clobbering
+	// the parent's own root body (which is what a same-named Parse on
+	// clone would otherwise replace).
+	if _, err := clone.New(name + "$blocks").Parse(e.blockSrc[name]); err != nil {
+		return nil, fmt.Errorf("template: %s: parsing blocks: %w", name, err)
+	}
+
+	e.extended[name] = clone
+	return clone, nil
+}
+
+// isolatedBase returns a freshly parsed, self-contained copy of the
+// plain (non-extends) base template named name, memoized in bases for
+// the current resolution pass. resolveAllExtends calls it for every
+// extends parent and, to cover bases that are only ever rendered
+// directly, for every remaining name in e.baseSources too.
+//
+// It reparses from e.baseSources rather than reusing
+// e.Templates.Lookup(name), because every plain template is registered
+// into the single tree shared by e.Templates: two unrelated layouts that
+// each declare a same-named {{ block "content" . }} would otherwise
+// silently clobber each other's default body, since Go's html/template
+// registers a block's default as a define in whichever associated-
+// template set it's parsed into, and e.Templates is one shared set. A
+// fresh, isolated parse per base layout can't collide with any other
+// layout's blocks.
+func (e *Engine) isolatedBase(name string, bases map[string]*template.Template) (*template.Template, error) {
+	if tmpl, ok := bases[name]; ok {
+		return tmpl, nil
+	}
+	src, ok := e.baseSources[name]
+	if !ok {
+		return nil, fmt.Errorf("template: base template %q not found", name)
+	}
+	tmpl := template.New(name)
+	tmpl.Delims(e.left, e.right)
+	tmpl.Funcs(e.currentFuncs())
+	if _, err := tmpl.Parse(src); err != nil {
+		return nil, fmt.Errorf("template: parsing base %q: %w", name, err)
+	}
+	bases[name] = tmpl
+	return tmpl, nil
+}
@@ -0,0 +1,125 @@
+package template
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/russross/blackfriday/v2"
+)
+
+// markdownPolicy sanitizes blackfriday's output before it is trusted as
+// template.HTML. blackfriday passes HTML embedded in the Markdown source
+// through verbatim, so unsanitized output is a stored-XSS hole for any
+// input the caller doesn't fully trust (the common case: user-authored
+// posts, comments, etc., mirroring why Caddy's own markdown module runs
+// through bluemonday too).
+var markdownPolicy = bluemonday.UGCPolicy()
+
+// httpIncludeTimeout bounds how long httpInclude waits for a response.
+const httpIncludeTimeout = 5 * time.Second
+
+var stripHTMLPattern = regexp.MustCompile(`<[^>]*>`)
+
+// DisableBuiltins prevents Load from registering the default FuncMap
+// helpers (include, markdown, fileExists, readFile, env, now,
+// httpInclude, stripHTML), leaving only funcs added via AddFunc/AddFuncs.
+// Call it before Load.
+func (e *Engine) DisableBuiltins() *Engine {
+	e.builtinsDisabled = true
+	return e
+}
+
+// builtinFuncs returns the default context-aware helpers. They are merged
+// into the FuncMap by Load, underneath e.funcmap, so any of them can be
+// overridden by a call to AddFunc/AddFuncs with the same name.
+func (e *Engine) builtinFuncs() template.FuncMap {
+	return template.FuncMap{
+		"include":     e.includeFunc,
+		"markdown":    markdownFunc,
+		"fileExists":  e.fileExistsFunc,
+		"readFile":    e.readFileFunc,
+		"env":         os.Getenv,
+		"now":         time.Now,
+		"httpInclude": httpIncludeFunc,
+		"stripHTML":   stripHTMLFunc,
+	}
+}
+
+// includeFunc renders the named template from the engine's fs.FS with the
+// given data and returns the result as safe HTML. Because it looks up the
+// template the same way Render does, an included template may itself
+// call include, to any depth. It does not lock e.mutex itself: it runs
+// inside a template that Render executes, and Render already holds
+// e.mutex (sync.RWMutex locks are not reentrant).
+func (e *Engine) includeFunc(name string, data any) (template.HTML, error) {
+	tmpl := e.extended[name]
+	if tmpl == nil {
+		tmpl = e.isolated[name]
+	}
+	if tmpl == nil {
+		tmpl = e.Templates.Lookup(name)
+	}
+	if tmpl == nil {
+		return "", fmt.Errorf("include: template %s does not exist", name)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("include %s: %w", name, err)
+	}
+	return template.HTML(buf.String()), nil
+}
+
+// markdownFunc renders CommonMark to HTML and sanitizes it with
+// markdownPolicy before returning it as safe HTML, since blackfriday
+// passes any raw HTML embedded in input through verbatim.
+func markdownFunc(input string) template.HTML {
+	unsafe := blackfriday.Run([]byte(input))
+	return template.HTML(markdownPolicy.SanitizeBytes(unsafe))
+}
+
+func (e *Engine) fileExistsFunc(path string) bool {
+	f, err := e.fileSystem.Open(path)
+	if err != nil {
+		return false
+	}
+	f.Close()
+	return true
+}
+
+func (e *Engine) readFileFunc(path string) (string, error) {
+	buf, err := readFile(path, e.fileSystem)
+	if err != nil {
+		return "", fmt.Errorf("readFile: %w", err)
+	}
+	return string(buf), nil
+}
+
+// httpIncludeFunc GETs url and inlines the response body, bounded by
+// httpIncludeTimeout so a slow or hanging upstream can't stall a render.
+func httpIncludeFunc(url string) (string, error) {
+	client := http.Client{Timeout: httpIncludeTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("httpInclude: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("httpInclude: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("httpInclude: %s: status %d", url, resp.StatusCode)
+	}
+	return string(body), nil
+}
+
+func stripHTMLFunc(input string) string {
+	return stripHTMLPattern.ReplaceAllString(input, "")
+}
@@ -0,0 +1,84 @@
+package template
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestIncludeRecursive(t *testing.T) {
+	fsys := fstest.MapFS{
+		"outer.tmpl":          {Data: []byte(`outer[{{ include "partials/inner" . }}]`)},
+		"partials/inner.tmpl": {Data: []byte(`inner[{{ include "partials/leaf" . }}]`)},
+		"partials/leaf.tmpl":  {Data: []byte(`leaf`)},
+	}
+	engine := NewFileSystem(fsys, ".tmpl")
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "outer", nil); err != nil {
+		t.Fatalf("Render() = %v", err)
+	}
+
+	want := "outer[inner[leaf]]"
+	if got := buf.String(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestIncludeMissingFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"outer.tmpl": {Data: []byte(`{{ include "does/not/exist" . }}`)},
+	}
+	engine := NewFileSystem(fsys, ".tmpl")
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+
+	var buf bytes.Buffer
+	err := engine.Render(&buf, "outer", nil)
+	if err == nil {
+		t.Fatal("Render() = nil, want error for missing include")
+	}
+}
+
+func TestMarkdownSafeHTML(t *testing.T) {
+	fsys := fstest.MapFS{
+		"page.tmpl": {Data: []byte(`{{ markdown . }}`)},
+	}
+	engine := NewFileSystem(fsys, ".tmpl")
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "page", "**bold**"); err != nil {
+		t.Fatalf("Render() = %v", err)
+	}
+
+	if got := buf.String(); !strings.Contains(got, "<strong>bold</strong>") {
+		t.Errorf("Render() = %q, want rendered markdown containing <strong>bold</strong> unescaped", got)
+	}
+}
+
+func TestMarkdownSanitizesEmbeddedHTML(t *testing.T) {
+	fsys := fstest.MapFS{
+		"page.tmpl": {Data: []byte(`{{ markdown . }}`)},
+	}
+	engine := NewFileSystem(fsys, ".tmpl")
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.Render(&buf, "page", `<script>alert(1)</script>`); err != nil {
+		t.Fatalf("Render() = %v", err)
+	}
+
+	if got := buf.String(); strings.Contains(got, "<script>") {
+		t.Errorf("Render() = %q, want raw HTML embedded in markdown sanitized away", got)
+	}
+}
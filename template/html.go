@@ -18,8 +18,14 @@ type Engine struct {
 	right string
 	// views folder
 	directory string
+	// on-disk directory backing fileSystem, set by New; empty when the
+	// engine was built from an arbitrary fs.FS and cannot be watched
+	osDir string
 	// fs.FS supports embedded files
 	fileSystem fs.FS
+	// layers backing fileSystem when it is a *layeredFS, top-most (highest
+	// priority) layer first; nil for engines with a single plain fs.FS
+	layers []fs.FS
 	// views extension
 	extension string
 	// layout variable name that incapsulates the template
@@ -36,12 +42,44 @@ type Engine struct {
 	funcmap map[string]any
 	// templates
 	Templates *template.Template
+	// logger used by Watch to report reload activity, nil disables logging
+	logger Logger
+	// closed by Close to stop a running Watch goroutine
+	closed chan struct{}
+	// builtinsDisabled skips registering the default FuncMap helpers
+	builtinsDisabled bool
+	// extended holds the compiled result of every template that declares
+	// {{ extends "parent" }}, keyed by its own name; see resolveExtends
+	extended map[string]*template.Template
+	// extendsOf[name] / blockSrc[name] record, for every template that
+	// declares {{ extends "parent" }}, its parent's name and its own
+	// {{ define "block" }} source (extends directive stripped). Kept on
+	// the engine, not just as Load locals, so Watch's reparse can redo
+	// extends resolution for a single changed file. See resolveExtends.
+	extendsOf map[string]string
+	blockSrc  map[string]string
+	// baseSources holds the raw source of every plain (non-extends)
+	// template, keyed by name, so a base layout can be re-parsed into
+	// its own isolated *template.Template whenever it's used as an
+	// extends parent: layouts are otherwise all registered into the one
+	// shared e.Templates tree, so two layouts defining a same-named
+	// {{ block }} would silently clobber each other's default body.
+	baseSources map[string]string
+	// isolated holds, for every name in baseSources, its own isolated
+	// *template.Template parsed in isolation from every other plain
+	// template. Render prefers this over Templates.Lookup so that a base
+	// layout with a {{ block "name" }} stays correctly isolated even when
+	// rendered directly (not reached through extends); see isolatedBase
+	// and resolveAllExtends.
+	isolated map[string]*template.Template
 }
 
 // New returns a HTML render engine
 func New(directory, extension string) *Engine {
 	fileSystem := os.DirFS(directory)
-	return NewFileSystem(fileSystem, extension)
+	engine := NewFileSystem(fileSystem, extension)
+	engine.osDir = directory
+	return engine
 }
 
 // NewFileSystem returns a HTML render engine
@@ -61,6 +99,36 @@ func NewFileSystem(fileSystem fs.FS, extension string) *Engine {
 	return engine
 }
 
+// NewLayeredFileSystem returns a HTML render engine backed by an ordered
+// stack of fs.FS layers, listed base layer first. A later layer's files
+// shadow files of the same name from an earlier layer, so an application
+// can ship a default template set (e.g. an embed.FS) as the base and let
+// an overlay (e.g. os.DirFS) override individual files without forking.
+func NewLayeredFileSystem(extension string, layers ...fs.FS) *Engine {
+	topDown := make([]fs.FS, len(layers))
+	for i, l := range layers {
+		topDown[len(layers)-1-i] = l
+	}
+	engine := NewFileSystem(&layeredFS{layers: topDown}, extension)
+	engine.layers = topDown
+	return engine
+}
+
+// Overlay stacks fsys on top of the engine's existing template sources,
+// so its files shadow files of the same name from any layer added before
+// it. It can be called repeatedly to build up an arbitrarily deep stack.
+func (e *Engine) Overlay(fsys fs.FS) *Engine {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.layers == nil {
+		e.layers = []fs.FS{e.fileSystem}
+	}
+	e.layers = append([]fs.FS{fsys}, e.layers...)
+	e.fileSystem = &layeredFS{layers: e.layers}
+	e.loaded = false
+	return e
+}
+
 // Layout defines the variable name that will incapsulate the template
 func (e *Engine) Layout(key string) *Engine {
 	e.layout = key
@@ -121,7 +189,16 @@ func (e *Engine) Load() error {
 
 	// Set template settings
 	e.Templates.Delims(e.left, e.right)
-	e.Templates.Funcs(e.funcmap)
+	funcs := e.currentFuncs()
+	e.Templates.Funcs(funcs)
+
+	// extendsOf[name] / blockSrc[name] hold templates that start with an
+	// {{ extends "parent" }} directive; they're resolved after the walk,
+	// once every plain (non-extending) template is registered and can
+	// serve as a base layout. See resolveExtends.
+	e.extendsOf = make(map[string]string)
+	e.blockSrc = make(map[string]string)
+	e.baseSources = make(map[string]string)
 
 	walkFn := func(path string, info fs.DirEntry, err error) error {
 		// Return error if exist
@@ -154,6 +231,12 @@ func (e *Engine) Load() error {
 		if err != nil {
 			return err
 		}
+		if parent, rest, ok := parseExtends(string(buf)); ok {
+			e.extendsOf[name] = parent
+			e.blockSrc[name] = rest
+			return nil
+		}
+		e.baseSources[name] = string(buf)
 		// Create new template associated with the current one
 		// This enable use to invoke other templates {{ template .. }}
 		_, err = e.Templates.New(name).Parse(string(buf))
@@ -166,9 +249,65 @@ func (e *Engine) Load() error {
 		}
 		return err
 	}
+	if err := fs.WalkDir(e.fileSystem, e.directory, walkFn); err != nil {
+		return err
+	}
+
+	if err := e.resolveAllExtends(); err != nil {
+		return err
+	}
+
 	// notify engine that we parsed all templates
 	e.loaded = true
-	return fs.WalkDir(e.fileSystem, e.directory, walkFn)
+	return nil
+}
+
+// currentFuncs returns the FuncMap that should be registered on any
+// *template.Template this engine parses: the built-in helpers (unless
+// disabled) overlaid with e.funcmap, so an AddFunc/AddFuncs call of the
+// same name always wins.
+func (e *Engine) currentFuncs() template.FuncMap {
+	funcs := template.FuncMap{}
+	if !e.builtinsDisabled {
+		for name, fn := range e.builtinFuncs() {
+			funcs[name] = fn
+		}
+	}
+	for name, fn := range e.funcmap {
+		funcs[name] = fn
+	}
+	return funcs
+}
+
+// resolveAllExtends (re)builds e.extended and e.isolated from the current
+// e.extendsOf / e.blockSrc / e.baseSources. Called by Load for the
+// initial parse and by Watch's reparse after a change to keep extends
+// chains and isolated base layouts in sync with edits to either a child
+// or a base layout.
+func (e *Engine) resolveAllExtends() error {
+	e.extended = make(map[string]*template.Template, len(e.extendsOf))
+	e.isolated = make(map[string]*template.Template, len(e.baseSources))
+	resolving := make(map[string]bool, len(e.extendsOf))
+	for name := range e.extendsOf {
+		if _, err := e.resolveExtends(name, e.isolated, resolving); err != nil {
+			return err
+		}
+		if e.debug {
+			fmt.Printf("views: parsed template: %s (extends %s)\n", name, e.extendsOf[name])
+		}
+	}
+	// isolatedBase above only isolates bases that are actually used as an
+	// extends parent; a base layout that is only ever rendered directly
+	// (no extends child uses it) still needs isolating from the other
+	// plain templates in e.Templates, or it can still be clobbered by
+	// another layout's same-named {{ block }}. Cover every remaining
+	// base here too.
+	for name := range e.baseSources {
+		if _, err := e.isolatedBase(name, e.isolated); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Render will execute the template name along with the given values.
@@ -182,17 +321,41 @@ func (e *Engine) Render(out io.Writer, template string, binding any, layout ...s
 		}
 	}
 
-	tmpl := e.Templates.Lookup(template)
+	// html/template cannot tolerate a Parse on e.Templates racing an
+	// Execute against it (Watch's reparse does exactly that under
+	// e.mutex), so every lookup and Execute below has to happen under
+	// e.mutex too. The layout branch additionally mutates lay's FuncMap,
+	// which isn't safe to do concurrently with another Render either,
+	// so it needs the write lock; the plain path only ever reads.
+	useLayout := len(layout) > 0 && layout[0] != ""
+	if useLayout {
+		e.mutex.Lock()
+		defer e.mutex.Unlock()
+	} else {
+		e.mutex.RLock()
+		defer e.mutex.RUnlock()
+	}
+
+	tmpl := e.extended[template]
+	if tmpl == nil {
+		// Prefer the isolated copy over e.Templates.Lookup: a plain base
+		// layout with a {{ block "name" }} is otherwise looked up from
+		// the single tree shared by every other plain template, so two
+		// unrelated layouts declaring the same block name would clobber
+		// each other even when neither is reached through extends.
+		tmpl = e.isolated[template]
+	}
+	if tmpl == nil {
+		tmpl = e.Templates.Lookup(template)
+	}
 	if tmpl == nil {
 		return fmt.Errorf("render: template %s does not exist", template)
 	}
-	if len(layout) > 0 && layout[0] != "" {
+	if useLayout {
 		lay := e.Templates.Lookup(layout[0])
 		if lay == nil {
 			return fmt.Errorf("render: layout %s does not exist", layout[0])
 		}
-		e.mutex.Lock()
-		defer e.mutex.Unlock()
 		lay.Funcs(map[string]any{
 			e.layout: func() error {
 				return tmpl.Execute(out, binding)
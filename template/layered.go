@@ -0,0 +1,78 @@
+package template
+
+import (
+	"errors"
+	"io/fs"
+	"sort"
+)
+
+// layeredFS unions a stack of fs.FS layers into a single fs.FS. layers[0]
+// is the highest-priority (top-most) layer: a name found in an earlier
+// layer shadows the same name in every layer that follows it.
+type layeredFS struct {
+	layers []fs.FS
+}
+
+var _ fs.FS = (*layeredFS)(nil)
+var _ fs.ReadDirFS = (*layeredFS)(nil)
+var _ fs.StatFS = (*layeredFS)(nil)
+
+// Open returns the file from the highest-priority layer that has it.
+func (l *layeredFS) Open(name string) (fs.File, error) {
+	for _, layer := range l.layers {
+		f, err := layer.Open(name)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// Stat resolves name against the same layer Open would use.
+func (l *layeredFS) Stat(name string) (fs.FileInfo, error) {
+	for _, layer := range l.layers {
+		info, err := fs.Stat(layer, name)
+		if err == nil {
+			return info, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir returns the union of every layer's entries for name, with
+// duplicate names resolved to the entry from the highest-priority layer.
+func (l *layeredFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	byName := make(map[string]fs.DirEntry)
+	anyFound := false
+	for _, layer := range l.layers {
+		entries, err := fs.ReadDir(layer, name)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		anyFound = true
+		for _, entry := range entries {
+			if _, shadowed := byName[entry.Name()]; !shadowed {
+				byName[entry.Name()] = entry
+			}
+		}
+	}
+	if !anyFound {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	out := make([]fs.DirEntry, 0, len(byName))
+	for _, entry := range byName {
+		out = append(out, entry)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name() < out[j].Name() })
+	return out, nil
+}
@@ -0,0 +1,81 @@
+package template
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLayeredFSOpenShadowing(t *testing.T) {
+	base := fstest.MapFS{
+		"index.tmpl":  {Data: []byte("base index")},
+		"footer.tmpl": {Data: []byte("base footer")},
+	}
+	overlay := fstest.MapFS{
+		"index.tmpl": {Data: []byte("overlay index")},
+	}
+	l := &layeredFS{layers: []fs.FS{overlay, base}}
+
+	f, err := l.Open("index.tmpl")
+	if err != nil {
+		t.Fatalf("Open(index.tmpl) = %v", err)
+	}
+	data, err := io.ReadAll(f)
+	f.Close()
+	if err != nil {
+		t.Fatalf("ReadAll() = %v", err)
+	}
+	if got := string(data); got != "overlay index" {
+		t.Errorf("Open(index.tmpl) = %q, want overlay to shadow base", got)
+	}
+
+	f, err = l.Open("footer.tmpl")
+	if err != nil {
+		t.Fatalf("Open(footer.tmpl) = %v", err)
+	}
+	data, err = io.ReadAll(f)
+	f.Close()
+	if err != nil {
+		t.Fatalf("ReadAll() = %v", err)
+	}
+	if got := string(data); got != "base footer" {
+		t.Errorf("Open(footer.tmpl) = %q, want fallthrough to base", got)
+	}
+
+	if _, err := l.Open("missing.tmpl"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Open(missing.tmpl) err = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestLayeredFSReadDirUnion(t *testing.T) {
+	base := fstest.MapFS{
+		"views/index.tmpl":  {Data: []byte("base index")},
+		"views/footer.tmpl": {Data: []byte("base footer")},
+	}
+	overlay := fstest.MapFS{
+		"views/index.tmpl":  {Data: []byte("overlay index")},
+		"views/header.tmpl": {Data: []byte("overlay header")},
+	}
+	l := &layeredFS{layers: []fs.FS{overlay, base}}
+
+	entries, err := l.ReadDir("views")
+	if err != nil {
+		t.Fatalf("ReadDir() = %v", err)
+	}
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Name()
+	}
+	want := []string{"footer.tmpl", "header.tmpl", "index.tmpl"}
+	if len(names) != len(want) {
+		t.Fatalf("ReadDir() names = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("ReadDir() names = %v, want %v", names, want)
+			break
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// debounceWindow batches bursts of filesystem events (editors typically
+// fire several in quick succession for a single save) before reparsing.
+const debounceWindow = 100 * time.Millisecond
+
+// Logger is the minimal logging interface used by Watch to report reload
+// activity and errors. *log.Logger satisfies this interface.
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+// SetLogger sets the logger used while watching for template changes.
+func (e *Engine) SetLogger(logger Logger) *Engine {
+	e.logger = logger
+	return e
+}
+
+// Watch starts a background goroutine that watches the engine's on-disk
+// directory for changes to files matching e.extension, and reparses only
+// the affected templates as they occur, swapping them into e.Templates
+// under e.mutex. It is a cheaper, race-free alternative to Reload(true),
+// which re-walks and re-parses everything on every Render call.
+//
+// Watch only works on engines created with New, since those are the only
+// ones backed by a real directory on disk; it returns an error otherwise.
+//
+// The returned channel receives an error whenever a reload fails. It is
+// closed once ctx is done or Close is called, at which point the watch
+// goroutine has fully stopped.
+func (e *Engine) Watch(ctx context.Context) (<-chan error, error) {
+	if e.osDir == "" {
+		return nil, fmt.Errorf("template: Watch requires an engine created with New")
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("template: creating watcher: %w", err)
+	}
+	err = filepath.WalkDir(e.osDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return watcher.Add(path)
+		}
+		return nil
+	})
+	if err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("template: watching %s: %w", e.osDir, err)
+	}
+
+	e.mutex.Lock()
+	e.closed = make(chan struct{})
+	closed := e.closed
+	e.mutex.Unlock()
+
+	errs := make(chan error)
+	go e.watchLoop(ctx, watcher, closed, errs)
+	return errs, nil
+}
+
+// Close stops a watch started by Watch. It is safe to call even if Watch
+// was never called, and safe to call more than once.
+func (e *Engine) Close() error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.closed != nil {
+		close(e.closed)
+		e.closed = nil
+	}
+	return nil
+}
+
+func (e *Engine) watchLoop(ctx context.Context, watcher *fsnotify.Watcher, closed <-chan struct{}, errs chan<- error) {
+	defer close(errs)
+	defer watcher.Close()
+
+	pending := make(map[string]struct{})
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	timerC := func() <-chan time.Time {
+		if timer == nil {
+			return nil
+		}
+		return timer.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-closed:
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if !strings.HasSuffix(event.Name, e.extension) {
+				continue
+			}
+			pending[event.Name] = struct{}{}
+			if timer == nil {
+				timer = time.NewTimer(debounceWindow)
+			} else {
+				timer.Reset(debounceWindow)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			e.emit(errs, ctx, closed, err)
+		case <-timerC():
+			names := make([]string, 0, len(pending))
+			for name := range pending {
+				names = append(names, name)
+			}
+			pending = make(map[string]struct{})
+			if err := e.reparse(names); err != nil {
+				e.emit(errs, ctx, closed, err)
+				continue
+			}
+			if e.logger != nil {
+				e.logger.Printf("views: reloaded %d template(s)", len(names))
+			}
+		}
+	}
+}
+
+// emit forwards err on errs, logs it if a Logger is set, and gives up
+// without blocking forever if ctx is cancelled or Close is called first.
+func (e *Engine) emit(errs chan<- error, ctx context.Context, closed <-chan struct{}, err error) {
+	if e.logger != nil {
+		e.logger.Printf("views: %v", err)
+	}
+	select {
+	case errs <- err:
+	case <-ctx.Done():
+	case <-closed:
+	}
+}
+
+// reparse reloads the given on-disk paths into the engine's template set
+// under e.mutex, replacing only the templates they name. Paths that no
+// longer exist (e.g. a Remove event) are skipped; html/template has no way
+// to remove a template that was already registered.
+//
+// A changed path may be an extends child, a base layout, or may have
+// switched between the two since it was last parsed, so reparse mirrors
+// Load's walkFn rather than always doing a plain Parse: it updates
+// e.extendsOf/e.blockSrc/e.baseSources for each path, then calls
+// resolveAllExtends to bring e.extended back in sync. A plain Parse alone
+// would choke on an extends child (extends isn't a registered func) and
+// would never refresh e.extended for either kind of change.
+func (e *Engine) reparse(paths []string) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	extendsChanged := false
+	for _, path := range paths {
+		rel, err := filepath.Rel(e.osDir, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		name = strings.TrimSuffix(name, e.extension)
+
+		buf, err := readFile(rel, e.fileSystem)
+		if err != nil {
+			// File was removed or renamed away; nothing to reparse.
+			continue
+		}
+
+		if parent, rest, ok := parseExtends(string(buf)); ok {
+			e.extendsOf[name] = parent
+			e.blockSrc[name] = rest
+			delete(e.baseSources, name)
+			extendsChanged = true
+			if e.debug {
+				fmt.Printf("views: reparsed template: %s (extends %s)\n", name, parent)
+			}
+			continue
+		}
+
+		delete(e.extendsOf, name)
+		delete(e.blockSrc, name)
+		e.baseSources[name] = string(buf)
+		if _, err := e.Templates.New(name).Parse(string(buf)); err != nil {
+			return fmt.Errorf("template: reparsing %s: %w", name, err)
+		}
+		// A base layout may be an extends parent; its block defaults
+		// could have changed too, so any extends chain built on it
+		// needs to be recompiled from this fresh source.
+		extendsChanged = true
+		if e.debug {
+			fmt.Printf("views: reparsed template: %s\n", name)
+		}
+	}
+
+	if extendsChanged {
+		if err := e.resolveAllExtends(); err != nil {
+			return fmt.Errorf("template: reparsing: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package template
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestWatchConcurrentRender exercises Render running concurrently with a
+// Watch-triggered reparse of the same template: before Render took
+// e.mutex, this reliably crashed with html/template panicking on a Parse
+// racing an Execute against the same *template.Template tree.
+func TestWatchConcurrentRender(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "index.tmpl")
+	if err := os.WriteFile(path, []byte("v1"), 0o644); err != nil {
+		t.Fatalf("WriteFile() = %v", err)
+	}
+
+	engine := New(dir, ".tmpl")
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs, err := engine.Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch() = %v", err)
+	}
+	go func() {
+		for range errs {
+		}
+	}()
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		var buf bytes.Buffer
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				buf.Reset()
+				_ = engine.Render(&buf, "index", nil)
+			}
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			content := []byte(time.Now().Format(time.RFC3339Nano))
+			_ = os.WriteFile(path, content, 0o644)
+			time.Sleep(2 * time.Millisecond)
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		time.Sleep(300 * time.Millisecond)
+		close(stop)
+	}()
+
+	wg.Wait()
+}